@@ -0,0 +1,82 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	scalesim "github.com/elankath/scaler-simulator"
+	"github.com/elankath/scaler-simulator/recommender"
+	"github.com/elankath/scaler-simulator/webutil"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// BurstSpec ramps up pods at a fixed rate for a fixed duration to exercise
+// the scale-up recommender under continuous pressure.
+type BurstSpec struct {
+	PodsPerSecond int
+	Duration      time.Duration
+	PodTemplate   PodSpec
+}
+
+// BurstTick is the outcome of one second of a burst: how many pods were
+// synthesized, and the scale-up recommendation computed in response.
+type BurstTick struct {
+	Tick           int                        `json:"tick"`
+	PodsCreated    int                        `json:"podsCreated"`
+	Recommendation recommender.Recommendation `json:"recommendation"`
+}
+
+// BurstResult is the full tick-by-tick trail of a burst run.
+type BurstResult struct {
+	Ticks []BurstTick `json:"ticks"`
+}
+
+// RunBurst synthesizes spec.PodsPerSecond pods every second for
+// spec.Duration, running the scale-up recommender after each tick and
+// recording its recommendation. namePrefix must be unique per call (e.g.
+// minted via a run registry) so concurrent bursts don't synthesize pods
+// with colliding names.
+func RunBurst(ctx context.Context, engine scalesim.Engine, shootNodes []corev1.Node, scenarioName, shootName string, weights recommender.StrategyWeights, logWriter http.ResponseWriter, namePrefix string, spec BurstSpec) (BurstResult, error) {
+	if spec.PodsPerSecond <= 0 {
+		return BurstResult{}, fmt.Errorf("podsPerSecond must be positive, got %d", spec.PodsPerSecond)
+	}
+
+	var result BurstResult
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	deadline := time.Now().Add(spec.Duration)
+	for tick := 0; time.Now().Before(deadline); tick++ {
+		if tick > 0 {
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-ticker.C:
+			}
+		}
+
+		podTemplate := spec.PodTemplate
+		podTemplate.Count = spec.PodsPerSecond
+		pods, err := SynthesizePods(ctx, engine.VirtualClusterAccess(), fmt.Sprintf("%s-%d", namePrefix, tick), podTemplate)
+		if err != nil {
+			return result, err
+		}
+		webutil.Log(logWriter, fmt.Sprintf("burst tick #%d: synthesized %d pods", tick, len(pods)))
+
+		rec := recommender.NewRecommender(engine, shootNodes, scenarioName, shootName, weights, logWriter)
+		recommendation, _, err := rec.Run(ctx)
+		if err != nil {
+			return result, err
+		}
+
+		result.Ticks = append(result.Ticks, BurstTick{
+			Tick:           tick,
+			PodsCreated:    len(pods),
+			Recommendation: recommendation,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,107 @@
+// Package loadgen programmatically synthesizes pods with configurable
+// resource requests, in the spirit of k8s.io/kubernetes/test/utils's pod/RC/
+// job runners, so scenarios can drive the recommender under load without
+// hand-writing YAML per case.
+package loadgen
+
+import (
+	"context"
+	"fmt"
+
+	scalesim "github.com/elankath/scaler-simulator"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OwnerKind is the workload kind synthesized pods are attributed to.
+type OwnerKind string
+
+const (
+	OwnerDeployment  OwnerKind = "Deployment"
+	OwnerJob         OwnerKind = "Job"
+	OwnerStatefulSet OwnerKind = "StatefulSet"
+)
+
+// PodSpec describes the synthetic pods to create.
+type PodSpec struct {
+	Count                     int
+	CPURequest                string
+	MemRequest                string
+	NodeSelector              map[string]string
+	Tolerations               []corev1.Toleration
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint
+	OwnerKind                 OwnerKind
+}
+
+// SynthesizePods creates spec.Count unscheduled pods matching spec in the
+// virtual cluster and returns the pods that were created.
+func SynthesizePods(ctx context.Context, virtualAccess scalesim.VirtualClusterAccess, namePrefix string, spec PodSpec) ([]*corev1.Pod, error) {
+	if spec.Count <= 0 {
+		return nil, fmt.Errorf("pod count must be positive, got %d", spec.Count)
+	}
+	cpuQty, err := resource.ParseQuantity(spec.CPURequest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cpuRequest %q: %w", spec.CPURequest, err)
+	}
+	memQty, err := resource.ParseQuantity(spec.MemRequest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid memRequest %q: %w", spec.MemRequest, err)
+	}
+	ownerKind := spec.OwnerKind
+	if ownerKind == "" {
+		ownerKind = OwnerDeployment
+	}
+	ownerName := namePrefix + "-owner"
+
+	pods := make([]*corev1.Pod, 0, spec.Count)
+	for i := 0; i < spec.Count; i++ {
+		pods = append(pods, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%d", namePrefix, i),
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: ownerAPIVersion(ownerKind),
+						Kind:       string(ownerKind),
+						Name:       ownerName,
+						Controller: boolPtr(true),
+					},
+				},
+			},
+			Spec: corev1.PodSpec{
+				NodeSelector:              spec.NodeSelector,
+				Tolerations:               spec.Tolerations,
+				TopologySpreadConstraints: spec.TopologySpreadConstraints,
+				Containers: []corev1.Container{
+					{
+						Name:  "synthetic",
+						Image: "registry.k8s.io/pause:3.9",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    cpuQty,
+								corev1.ResourceMemory: memQty,
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+
+	if err := virtualAccess.AddPods(ctx, pods...); err != nil {
+		return nil, err
+	}
+	return pods, nil
+}
+
+func ownerAPIVersion(kind OwnerKind) string {
+	if kind == OwnerJob {
+		return "batch/v1"
+	}
+	return "apps/v1"
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
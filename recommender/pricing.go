@@ -0,0 +1,66 @@
+package recommender
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// defaultHourlyCostByMachineType is a placeholder price-list used until the
+// recommender is wired up to a live cloud-provider pricing API.
+var defaultHourlyCostByMachineType = map[string]float64{
+	"m5.large":   0.096,
+	"m5.xlarge":  0.192,
+	"m5.2xlarge": 0.384,
+	"c5.large":   0.085,
+	"c5.xlarge":  0.17,
+}
+
+// defaultResourcesByMachineType is a placeholder instance-type table mapping
+// each supported machine type to its node CPU and memory capacity, used
+// until the recommender is wired up to a live cloud-provider instance-type
+// API. Kept in step with defaultHourlyCostByMachineType.
+var defaultResourcesByMachineType = map[string]corev1.ResourceList{
+	"m5.large":   {corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("8Gi")},
+	"m5.xlarge":  {corev1.ResourceCPU: resource.MustParse("4"), corev1.ResourceMemory: resource.MustParse("16Gi")},
+	"m5.2xlarge": {corev1.ResourceCPU: resource.MustParse("8"), corev1.ResourceMemory: resource.MustParse("32Gi")},
+	"c5.large":   {corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("4Gi")},
+	"c5.xlarge":  {corev1.ResourceCPU: resource.MustParse("4"), corev1.ResourceMemory: resource.MustParse("8Gi")},
+}
+
+// capacityForMachineType returns the node capacity (CPU and memory) of
+// machineType, so a simulated node can be scored by how much of that
+// capacity its scheduled pods actually use.
+func capacityForMachineType(machineType string) (corev1.ResourceList, error) {
+	capacity, ok := defaultResourcesByMachineType[machineType]
+	if !ok {
+		return nil, fmt.Errorf("no capacity configured for machine type %q", machineType)
+	}
+	return capacity.DeepCopy(), nil
+}
+
+// InstancePricing resolves the on-demand hourly cost of a machine type so the
+// recommender can weigh cost against waste when scoring candidate node pools.
+type InstancePricing interface {
+	HourlyCost(machineType string) (float64, error)
+}
+
+// StaticInstancePricing serves hourly costs from an in-memory table. It is
+// meant to be seeded from a provider price-list until a live pricing API is
+// wired in.
+type StaticInstancePricing struct {
+	hourlyCostByMachineType map[string]float64
+}
+
+func NewStaticInstancePricing(hourlyCostByMachineType map[string]float64) *StaticInstancePricing {
+	return &StaticInstancePricing{hourlyCostByMachineType: hourlyCostByMachineType}
+}
+
+func (p *StaticInstancePricing) HourlyCost(machineType string) (float64, error) {
+	cost, ok := p.hourlyCostByMachineType[machineType]
+	if !ok {
+		return 0, fmt.Errorf("no hourly cost configured for machine type %q", machineType)
+	}
+	return cost, nil
+}
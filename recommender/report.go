@@ -0,0 +1,64 @@
+package recommender
+
+import (
+	"strings"
+	"time"
+)
+
+// aggregate bundles multiple errors into a single error, modelled on
+// client-go's utilerrors.Aggregate: each underlying error is preserved and
+// can be inspected via Errors, while Error() renders all of them.
+type aggregate struct {
+	errs []error
+}
+
+// newAggregate returns nil if errs is empty, the lone error if there is
+// exactly one, or an aggregate wrapping all of them otherwise.
+func newAggregate(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return &aggregate{errs: errs}
+	}
+}
+
+func (a *aggregate) Error() string {
+	msgs := make([]string, 0, len(a.errs))
+	for _, err := range a.errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (a *aggregate) Errors() []error {
+	return a.errs
+}
+
+// PoolStatus reports how a single node pool fared in one simulation round.
+type PoolStatus struct {
+	NodePool         string        `json:"nodePool"`
+	HasWinner        bool          `json:"hasWinner"`
+	WinnerScore      float64       `json:"winnerScore,omitempty"`
+	Zone             string        `json:"zone,omitempty"`
+	UnscheduledCount int           `json:"unscheduledCount"`
+	Error            string        `json:"error,omitempty"`
+	Elapsed          time.Duration `json:"elapsed"`
+}
+
+// RoundReport captures the per-pool outcome of a single scale-up simulation
+// round (one iteration of the Recommender.Run loop).
+type RoundReport struct {
+	RunNumber int          `json:"runNumber"`
+	Pools     []PoolStatus `json:"pools"`
+}
+
+// RunReport is the full debugging trail for one Recommender.Run invocation:
+// every round it simulated, and the final recommendation (if any) or error.
+type RunReport struct {
+	Recommendation Recommendation `json:"recommendation"`
+	Rounds         []RoundReport  `json:"rounds"`
+	Error          string         `json:"error,omitempty"`
+}
@@ -0,0 +1,32 @@
+package recommender
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewAggregate(t *testing.T) {
+	if err := newAggregate(nil); err != nil {
+		t.Errorf("newAggregate(nil) = %v, want nil", err)
+	}
+
+	single := errors.New("boom")
+	if err := newAggregate([]error{single}); err != single {
+		t.Errorf("newAggregate with one error = %v, want the original error", err)
+	}
+
+	err := newAggregate([]error{errors.New("first"), errors.New("second")})
+	if err == nil {
+		t.Fatal("expected a non-nil aggregate error")
+	}
+	agg, ok := err.(*aggregate)
+	if !ok {
+		t.Fatalf("newAggregate returned %T, want *aggregate", err)
+	}
+	if len(agg.Errors()) != 2 {
+		t.Errorf("Errors() returned %d errors, want 2", len(agg.Errors()))
+	}
+	if want := "first; second"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,254 @@
+package recommender
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	scalesim "github.com/elankath/scaler-simulator"
+	"github.com/elankath/scaler-simulator/simutil"
+	"github.com/elankath/scaler-simulator/webutil"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	scaleDownDisabledAnnotation = "cluster-autoscaler.kubernetes.io/scale-down-disabled"
+	safeToEvictAnnotation       = "cluster-autoscaler.kubernetes.io/safe-to-evict"
+	evictionWaitTimeout         = 10 * time.Second
+)
+
+// ScaleDownRecommendation is the outcome of running the scale-down simulation
+// across all candidate nodes.
+type ScaleDownRecommendation struct {
+	RemovedNodes  []string           `json:"removedNodes"`
+	PodMigrations map[string]string  `json:"podMigrations"` // pod namespace/name -> new node name
+	DollarSavings map[string]float64 `json:"dollarSavings"` // node name -> hourly savings
+}
+
+// ScaleDownRecommender simulates, in descending machine-cost order, whether a
+// node can be safely drained: every evicted pod must reschedule elsewhere and
+// every owning workload's PodDisruptionBudget must remain satisfied.
+type ScaleDownRecommender struct {
+	virtualAccess scalesim.VirtualClusterAccess
+	pricing       InstancePricing
+	logWriter     http.ResponseWriter
+}
+
+func NewScaleDownRecommender(virtualAccess scalesim.VirtualClusterAccess, logWriter http.ResponseWriter) *ScaleDownRecommender {
+	return &ScaleDownRecommender{
+		virtualAccess: virtualAccess,
+		pricing:       NewStaticInstancePricing(defaultHourlyCostByMachineType),
+		logWriter:     logWriter,
+	}
+}
+
+// ScaleDownOrderedByDescendingCost evaluates candidateNodes from the most to
+// the least expensive and virtually removes every node whose pods can be
+// safely rescheduled without breaking any PodDisruptionBudget.
+func ScaleDownOrderedByDescendingCost(ctx context.Context, virtualAccess scalesim.VirtualClusterAccess, w http.ResponseWriter, candidateNodes []corev1.Node) (ScaleDownRecommendation, error) {
+	return NewScaleDownRecommender(virtualAccess, w).Run(ctx, candidateNodes)
+}
+
+func (s *ScaleDownRecommender) Run(ctx context.Context, candidateNodes []corev1.Node) (ScaleDownRecommendation, error) {
+	recommendation := ScaleDownRecommendation{
+		PodMigrations: map[string]string{},
+		DollarSavings: map[string]float64{},
+	}
+
+	nodes := s.orderByDescendingCost(candidateNodes)
+	for _, node := range nodes {
+		if !s.isRemovalCandidate(node) {
+			continue
+		}
+
+		pods, err := s.podsOnNode(ctx, node.Name)
+		if err != nil {
+			return recommendation, err
+		}
+		if len(pods) == 0 {
+			if err := s.virtualAccess.DeleteNode(ctx, node.Name); err != nil {
+				return recommendation, err
+			}
+			recommendation.RemovedNodes = append(recommendation.RemovedNodes, node.Name)
+			continue
+		}
+		if !s.allPodsEvictable(pods) {
+			webutil.Log(s.logWriter, fmt.Sprintf("node %s has a pod with safe-to-evict=false, skipping", node.Name))
+			continue
+		}
+
+		migrations, ok, err := s.tryEvictAndReschedule(ctx, node, pods)
+		if err != nil {
+			return recommendation, err
+		}
+		if !ok {
+			continue
+		}
+
+		if err := s.virtualAccess.DeleteNode(ctx, node.Name); err != nil {
+			return recommendation, err
+		}
+		recommendation.RemovedNodes = append(recommendation.RemovedNodes, node.Name)
+		for podKey, newNode := range migrations {
+			recommendation.PodMigrations[podKey] = newNode
+		}
+		if cost, err := s.pricing.HourlyCost(node.Labels["node.kubernetes.io/instance-type"]); err == nil {
+			recommendation.DollarSavings[node.Name] = cost
+		}
+	}
+
+	return recommendation, nil
+}
+
+func (s *ScaleDownRecommender) orderByDescendingCost(nodes []corev1.Node) []corev1.Node {
+	ordered := make([]corev1.Node, len(nodes))
+	copy(ordered, nodes)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		costI, _ := s.pricing.HourlyCost(ordered[i].Labels["node.kubernetes.io/instance-type"])
+		costJ, _ := s.pricing.HourlyCost(ordered[j].Labels["node.kubernetes.io/instance-type"])
+		return costI > costJ
+	})
+	return ordered
+}
+
+func (s *ScaleDownRecommender) isRemovalCandidate(node corev1.Node) bool {
+	if node.Annotations[scaleDownDisabledAnnotation] == "true" {
+		return false
+	}
+	return true
+}
+
+func (s *ScaleDownRecommender) podsOnNode(ctx context.Context, nodeName string) ([]corev1.Pod, error) {
+	allPods, err := s.virtualAccess.ListPods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var onNode []corev1.Pod
+	for _, pod := range allPods {
+		if pod.Spec.NodeName != nodeName {
+			continue
+		}
+		if isDaemonSetPod(pod) {
+			continue
+		}
+		onNode = append(onNode, pod)
+	}
+	return onNode, nil
+}
+
+func (s *ScaleDownRecommender) allPodsEvictable(pods []corev1.Pod) bool {
+	for _, pod := range pods {
+		if pod.Annotations[safeToEvictAnnotation] == "false" {
+			return false
+		}
+	}
+	return true
+}
+
+// tryEvictAndReschedule simulates deleting every pod on node and letting the
+// scheduler re-place them on the remaining nodes. The removal is only
+// accepted if every pod reschedules and every owning workload's
+// PodDisruptionBudget still holds afterwards; otherwise the virtual cluster
+// is restored to the snapshot taken before any pod was deleted, so a
+// mid-loop PDB refusal or a reschedule failure never leaves pods
+// permanently evicted.
+func (s *ScaleDownRecommender) tryEvictAndReschedule(ctx context.Context, node corev1.Node, pods []corev1.Pod) (map[string]string, bool, error) {
+	pdbs, err := s.virtualAccess.ListPodDisruptionBudgets(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	remainingDisruptions := make(map[string]int32, len(pdbs))
+	for _, pdb := range pdbs {
+		remainingDisruptions[pdb.Namespace+"/"+pdb.Name] = pdb.Status.DisruptionsAllowed
+	}
+
+	snapshotID, err := s.virtualAccess.Snapshot(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		if err := s.virtualAccess.Restore(ctx, snapshotID); err != nil {
+			webutil.Log(s.logWriter, fmt.Sprintf("failed to restore virtual cluster after aborted eviction of node %s: %v", node.Name, err))
+		}
+	}()
+
+	evictStart := time.Now()
+	for _, pod := range pods {
+		if !s.pdbAllowsEviction(pod, pdbs, remainingDisruptions) {
+			return nil, false, nil
+		}
+		if err := s.virtualAccess.DeletePod(ctx, pod.Namespace, pod.Name); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if _, err := simutil.WaitTillNoUnscheduledPodsOrTimeout(ctx, s.virtualAccess, evictionWaitTimeout, evictStart); err != nil {
+		return nil, false, nil
+	}
+
+	migrations := map[string]string{}
+	allPods, err := s.virtualAccess.ListPods(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	rescheduled := make(map[string]corev1.Pod, len(pods))
+	for _, pod := range allPods {
+		rescheduled[pod.Namespace+"/"+pod.Name] = pod
+	}
+	for _, pod := range pods {
+		newPod, ok := rescheduled[pod.Namespace+"/"+pod.Name]
+		if !ok || newPod.Spec.NodeName == "" {
+			return nil, false, nil
+		}
+		migrations[pod.Namespace+"/"+pod.Name] = newPod.Spec.NodeName
+	}
+
+	committed = true
+	return migrations, true, nil
+}
+
+// pdbAllowsEviction reports whether evicting pod keeps its matching
+// PodDisruptionBudgets (if any) within their minAvailable/maxUnavailable
+// invariants. remainingDisruptions tracks each PDB's allowance as it's
+// consumed across the pods on the same node, so a second pod covered by a
+// PDB with only one disruption allowed is correctly refused once the first
+// pod has used it up.
+func (s *ScaleDownRecommender) pdbAllowsEviction(pod corev1.Pod, pdbs []policyv1.PodDisruptionBudget, remainingDisruptions map[string]int32) bool {
+	var matched []string
+	for _, pdb := range pdbs {
+		if pdb.Namespace != pod.Namespace {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		key := pdb.Namespace + "/" + pdb.Name
+		if remainingDisruptions[key] <= 0 {
+			return false
+		}
+		matched = append(matched, key)
+	}
+	for _, key := range matched {
+		remainingDisruptions[key]--
+	}
+	return true
+}
+
+func isDaemonSetPod(pod corev1.Pod) bool {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package recommender
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func podInNamespace(namespace string, labels map[string]string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Labels:    labels,
+		},
+	}
+}
+
+func pdbAllowing(namespace string, labels map[string]string, allowed int32) policyv1.PodDisruptionBudget {
+	return policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "pdb"},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+		},
+		Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: allowed},
+	}
+}
+
+func TestPdbAllowsEvictionConsumesAllowanceAcrossPods(t *testing.T) {
+	s := &ScaleDownRecommender{}
+	labels := map[string]string{"app": "web"}
+	pdbs := []policyv1.PodDisruptionBudget{pdbAllowing("default", labels, 1)}
+	remaining := map[string]int32{"default/pdb": 1}
+
+	pod1 := podInNamespace("default", labels)
+	pod2 := podInNamespace("default", labels)
+
+	if !s.pdbAllowsEviction(pod1, pdbs, remaining) {
+		t.Fatal("expected first pod's eviction to be allowed")
+	}
+	if s.pdbAllowsEviction(pod2, pdbs, remaining) {
+		t.Fatal("expected second pod's eviction to be refused once the PDB's allowance is exhausted")
+	}
+}
+
+func TestPdbAllowsEvictionIgnoresNonMatchingPDBs(t *testing.T) {
+	s := &ScaleDownRecommender{}
+	pdbs := []policyv1.PodDisruptionBudget{
+		pdbAllowing("default", map[string]string{"app": "other"}, 0),
+		pdbAllowing("other-ns", map[string]string{"app": "web"}, 0),
+	}
+	remaining := map[string]int32{"default/pdb": 0, "other-ns/pdb": 0}
+	pod := podInNamespace("default", map[string]string{"app": "web"})
+
+	if !s.pdbAllowsEviction(pod, pdbs, remaining) {
+		t.Fatal("expected eviction to be allowed when no PDB selector matches the pod")
+	}
+}
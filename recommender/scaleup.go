@@ -2,17 +2,17 @@ package recommender
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"math"
 	"net/http"
-	"strconv"
-	"sync"
+	"time"
 
 	scalesim "github.com/elankath/scaler-simulator"
+	"github.com/elankath/scaler-simulator/simutil"
 	"github.com/elankath/scaler-simulator/webutil"
 	"github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 /*
@@ -20,25 +20,40 @@ import (
 		unscheduledPods = determine unscheduled pods
 		if noUnscheduledPods then exit early
 		- runSimulation
- 		  - Start a go-routine for each of candidate nodePool which are eligible
+ 		  - Simulate each candidate nodePool which is eligible, one at a time
 				- eligibility: max is not yet reached for that nodePool
-              For each go-routine:
-                Setup:
-                    - create a unique label that will get added to all nodes and pods
-                	- copy previous winner nodes and a taint.
-                	- copy the deployed pods with node names assigned and add toleration to the taint.
+            For each nodePool (sequential: they share one virtual cluster
+            snapshotted/restored around each mutation, so concurrent pools
+            would stomp on each other's in-flight state):
+              Setup:
+                  - create a unique label that will get added to all nodes and pods
+              	- snapshot the virtual cluster; previous winners are already in it as real, untainted nodes.
+              	- restore that snapshot once the zone's node has been scored.
 	            - scale up one node, add a taint and only copy of pods will have toleration to that taint.
-                - copy of unscheduled pods, add a toleration for this taint.
-                - wait for pods to be scheduled
-                - compute node score.
+              - copy of unscheduled pods, add a toleration for this taint.
+              - wait for pods to be scheduled
+              - compute node score.
 	}
 */
 
+const (
+	simulationRunLabelKey   = "app.kubernetes.io/simulation-run"
+	simulationOriginAnnoKey = "simulation.scaler-simulator/origin-pod"
+	taintKeyPrefix          = "simulation.scaler-simulator/scale-up"
+	schedulingWaitTimeout   = 10 * time.Second
+)
+
+// StrategyWeights controls how heavily the recommender weighs waste reduction
+// against hourly cost when scoring candidate node pools. The two weights are
+// expected to sum to 1, but are not normalized here so callers can express a
+// stronger-than-1 bias if they want to.
 type StrategyWeights struct {
 	LeastWaste float64
 	LeastCost  float64
 }
 
+// Recommendation maps a node pool name to the number of nodes recommended to
+// be added to it.
 type Recommendation map[string]int
 
 type Recommender struct {
@@ -47,12 +62,19 @@ type Recommender struct {
 	scenarioName    string
 	shootName       string
 	strategyWeights StrategyWeights
+	pricing         InstancePricing
 	logWriter       http.ResponseWriter
 }
 
+// runResult is the outcome of simulating the addition of a single node of a
+// node pool's machine type into one of its zones.
 type runResult struct {
+	nodePoolName    string
+	zone            string
+	node            corev1.Node
 	result          scalesim.NodeRunResult
 	unscheduledPods []corev1.Pod
+	elapsed         time.Duration
 	err             error
 }
 
@@ -63,43 +85,68 @@ func NewRecommender(engine scalesim.Engine, shootNodes []corev1.Node, scenarioNa
 		scenarioName:    scenarioName,
 		shootName:       shootName,
 		strategyWeights: strategyWeights,
+		pricing:         NewStaticInstancePricing(defaultHourlyCostByMachineType),
 		logWriter:       logWriter,
 	}
 }
 
-func (r *Recommender) Run(ctx context.Context) (Recommendation, error) {
+func (r *Recommender) Run(ctx context.Context) (Recommendation, RunReport, error) {
 	recommendation := make(Recommendation)
+	report := RunReport{Recommendation: recommendation}
+
 	unscheduledPods, err := r.engine.VirtualClusterAccess().ListPods(ctx)
 	if err != nil {
-		return recommendation, err
+		report.Error = err.Error()
+		return recommendation, report, err
 	}
 	var runNumber int
 	shoot, err := r.getShoot()
 	if err != nil {
 		webutil.InternalError(r.logWriter, err)
-		return recommendation, err
+		report.Error = err.Error()
+		return recommendation, report, err
 	}
-	var winningNodeResult *scalesim.NodeRunResult
 	for {
+		select {
+		case <-ctx.Done():
+			report.Error = ctx.Err().Error()
+			return recommendation, report, ctx.Err()
+		default:
+		}
 		runNumber++
 		webutil.Log(r.logWriter, fmt.Sprintf("scale-up recommender run #%d started...", runNumber))
 		if len(unscheduledPods) == 0 {
 			webutil.Log(r.logWriter, "All pods are scheduled. Exiting the loop...")
 			break
 		}
-		winningNodeResult, unscheduledPods, err = r.runSimulation(ctx, shoot, unscheduledPods, runNumber)
+		winner, round, err := r.runSimulation(ctx, shoot, unscheduledPods, runNumber)
+		report.Rounds = append(report.Rounds, round)
 		if err != nil {
 			webutil.Log(r.logWriter, fmt.Sprintf("Unable to get eligible node pools for shoot %s, err: %v", shoot.Name, err))
-			break
+			report.Error = err.Error()
+			return recommendation, report, err
 		}
-		if winningNodeResult == nil {
-			webutil.Log(r.logWriter, fmt.Sprintf("scale-up recommender run #%d, no winner could be identified. This will happen when no pods could be assgined. No more runs are required, exiting early", runCounter))
+		if winner == nil {
+			webutil.Log(r.logWriter, fmt.Sprintf("scale-up recommender run #%d, no winner could be identified. This will happen when no node pool is eligible for scale-up. No more runs are required, exiting early", runNumber))
 			break
 		}
-		webutil.Log(r.logWriter, fmt.Sprintf("For scale-up recommender run #%d, winning score is: %v", runNumber, winningNodeResult))
+		webutil.Log(r.logWriter, fmt.Sprintf("For scale-up recommender run #%d, winning node pool is %q in zone %q with score %v", runNumber, winner.nodePoolName, winner.zone, winner.result.CumulativeScore))
+
+		winnerOrdinal := recommendation[winner.nodePoolName] + 1
+		recommendation[winner.nodePoolName] = winnerOrdinal
+
+		winningNode := winner.node.DeepCopy()
+		winningNode.Name = fmt.Sprintf("%s-%d", winner.nodePoolName, winnerOrdinal)
+		winningNode.Spec.Taints = nil
+		delete(winningNode.Labels, simulationRunLabelKey)
+		if err := r.engine.VirtualClusterAccess().AddNodes(ctx, winningNode); err != nil {
+			report.Error = err.Error()
+			return recommendation, report, err
+		}
+		unscheduledPods = winner.unscheduledPods
 	}
 
-	return recommendation, nil
+	return recommendation, report, nil
 }
 
 func (r *Recommender) getShoot() (*v1beta1.Shoot, error) {
@@ -116,88 +163,310 @@ func (r *Recommender) getShoot() (*v1beta1.Shoot, error) {
 // 1 pod will get assigned to A. 5 pending. 3 Nodes will be scale up. (1-a, 1-b, 1-c)
 // if you count existing nodes and pods, then only 2 nodes are needed.
 
-func (r *Recommender) runSimulation(ctx context.Context, shoot *v1beta1.Shoot, pods []corev1.Pod, runNum int) (*scalesim.NodeRunResult, []corev1.Pod, error) {
-	/*
-		    1. getEligibleNodePools
-			2. For each nodePool, start a go routine. Each go routine will return a node score.
-			3. Collect the scores and return
-
-			Inside each go routine:-
-				1. Setup:-
-					 - create a unique label that will get added to all nodes and pods (for helping in clean up)
-				     - copy previous winner nodes and add a taint.
-		             - copy the deployed pods with node names assigned and add a toleration to the taint.
-				2. For each zone in the nodePool:-
-					- scale up one node
-					- wait for assignment of pods (5 sec delay),
-					- calculate the score.
-			    	- Reset the state
-			    3. Compute the winning score for this nodePool and push to the result channel.
-	*/
+func (r *Recommender) runSimulation(ctx context.Context, shoot *v1beta1.Shoot, pods []corev1.Pod, runNum int) (*runResult, RoundReport, error) {
+	round := RoundReport{RunNumber: runNum}
+
 	eligibleNodePools, err := r.getEligibleNodePools(ctx, shoot)
 	if err != nil {
-		return nil, nil, err
+		return nil, round, err
+	}
+	if len(eligibleNodePools) == 0 {
+		return nil, round, nil
 	}
-	var results []runResult
+
+	referenceCost := r.referenceHourlyCost(eligibleNodePools)
 
 	resultCh := make(chan runResult, len(eligibleNodePools))
-	go r.triggerNodePoolSimulations(ctx, eligibleNodePools, resultCh, runNum)
+	go r.triggerNodePoolSimulations(ctx, eligibleNodePools, pods, referenceCost, resultCh, runNum)
 
-	// label, taint, result chan, error chan, close chan
-	var errs error
+	var results []runResult
+	var errs []error
 	for result := range resultCh {
+		round.Pools = append(round.Pools, poolStatusFor(result))
 		if result.err != nil {
-			_ = errors.Join(errs, err)
-		} else {
-			results = append(results, result)
+			errs = append(errs, fmt.Errorf("node pool %s: %w", result.nodePoolName, result.err))
+			continue
 		}
+		results = append(results, result)
 	}
-	if errs != nil {
-		return nil, nil, err
+	// A pool failing to simulate shouldn't sink the whole round as long as
+	// another pool produced a usable winner; its error is still recorded on
+	// the round report for later inspection via GET /api/runs/{id}/report.
+	if len(results) == 0 {
+		return nil, round, newAggregate(errs)
+	}
+	winner := getWinner(results)
+	return &winner, round, nil
+}
+
+func poolStatusFor(result runResult) PoolStatus {
+	status := PoolStatus{
+		NodePool: result.nodePoolName,
+		Elapsed:  result.elapsed,
+	}
+	if result.err != nil {
+		status.Error = result.err.Error()
+		return status
 	}
-	winningResult := getWinner(results)
-	return &winningResult.result, winningResult.unscheduledPods, nil
+	status.HasWinner = true
+	status.WinnerScore = result.result.CumulativeScore
+	status.Zone = result.zone
+	status.UnscheduledCount = len(result.unscheduledPods)
+	return status
 }
 
-func (r *Recommender) triggerNodePoolSimulations(ctx context.Context, nodePools []scalesim.NodePool, resultCh chan runResult, runNum int) {
-	wg := &sync.WaitGroup{}
+// referenceHourlyCost returns the hourly cost of the most expensive machine type amongst the
+// eligible node pools so that individual cost scores can be normalized to the
+// 0..1 range expected by CumulativeScore.
+func (r *Recommender) referenceHourlyCost(nodePools []scalesim.NodePool) float64 {
+	var maxCost float64
+	for _, np := range nodePools {
+		cost, err := r.pricing.HourlyCost(np.MachineType)
+		if err != nil {
+			continue
+		}
+		if cost > maxCost {
+			maxCost = cost
+		}
+	}
+	if maxCost == 0 {
+		maxCost = 1
+	}
+	return maxCost
+}
+
+// triggerNodePoolSimulations runs each eligible node pool's simulation in
+// turn. This must stay sequential: runSimulationForZone snapshots and
+// restores the one shared virtual cluster around its mutations, and two
+// pools simulating concurrently would restore each other's in-flight nodes
+// and pods out from under them.
+func (r *Recommender) triggerNodePoolSimulations(ctx context.Context, nodePools []scalesim.NodePool, pods []corev1.Pod, referenceCost float64, resultCh chan runResult, runNum int) {
 	for _, nodePool := range nodePools {
-		wg.Add(1)
-		go r.runSimulationForNodePool(ctx, wg, nodePool, resultCh, runNum)
+		r.runSimulationForNodePool(ctx, nodePool, pods, referenceCost, resultCh, runNum)
 	}
-	wg.Wait()
 	close(resultCh)
 }
 
-func (r *Recommender) runSimulationForNodePool(ctx context.Context, wg *sync.WaitGroup, nodePool scalesim.NodePool, resultCh chan runResult, runNum int) {
-	defer wg.Done()
-	runRes := runResult{}
+// runSimulationForNodePool simulates scaling up nodePool by exactly one node
+// in each of its zones and reports the best-scoring zone as this pool's
+// candidate. A zone that fails to simulate is logged and skipped rather than
+// dropping the whole pool, so one flaky zone doesn't discard an otherwise
+// valid winner already found in an earlier zone; the pool is only reported
+// as failed if every zone errored.
+func (r *Recommender) runSimulationForNodePool(ctx context.Context, nodePool scalesim.NodePool, pods []corev1.Pod, referenceCost float64, resultCh chan runResult, runNum int) {
+	var best *runResult
+	var zoneErrs []error
+	for zoneIdx, zone := range nodePool.Zones {
+		zoneStart := time.Now()
+		select {
+		case <-ctx.Done():
+			resultCh <- runResult{nodePoolName: nodePool.Name, elapsed: time.Since(zoneStart), err: ctx.Err()}
+			return
+		default:
+		}
+		zoneResult := r.runSimulationForZone(ctx, nodePool, zone, pods, referenceCost, runNum, zoneIdx)
+		zoneResult.nodePoolName = nodePool.Name
+		zoneResult.elapsed = time.Since(zoneStart)
+		if zoneResult.err != nil {
+			webutil.Log(r.logWriter, fmt.Sprintf("node pool %s zone %s: simulation failed, skipping this zone: %v", nodePool.Name, zone, zoneResult.err))
+			zoneErrs = append(zoneErrs, fmt.Errorf("zone %s: %w", zone, zoneResult.err))
+			continue
+		}
+		if best == nil || zoneResult.result.CumulativeScore < best.result.CumulativeScore {
+			best = &zoneResult
+		}
+	}
+	if best != nil {
+		resultCh <- *best
+		return
+	}
+	if len(zoneErrs) > 0 {
+		resultCh <- runResult{nodePoolName: nodePool.Name, err: newAggregate(zoneErrs)}
+	}
+}
 
-	labelKey := "app.kubernetes.io/simulation-run"
-	labelValue := nodePool.Name + "-" + strconv.Itoa(runNum)
+func (r *Recommender) runSimulationForZone(ctx context.Context, nodePool scalesim.NodePool, zone string, pods []corev1.Pod, referenceCost float64, runNum, zoneIdx int) runResult {
+	labelValue := fmt.Sprintf("%s-%d-%d", nodePool.Name, runNum, zoneIdx)
+	taintKey := fmt.Sprintf("%s/%s", taintKeyPrefix, labelValue)
 
-	nodes, err := r.engine.VirtualClusterAccess().ListNodes(ctx)
+	snapshotID, err := r.engine.VirtualClusterAccess().Snapshot(ctx)
 	if err != nil {
-		runRes.err = err
-		resultCh <- runRes
-		return
+		return runResult{err: err}
+	}
+	defer func() {
+		if err := r.engine.VirtualClusterAccess().Restore(ctx, snapshotID); err != nil {
+			r.logError(err)
+		}
+	}()
+
+	simNode, err := newSimulationNode(nodePool, zone, labelValue, taintKey)
+	if err != nil {
+		return runResult{err: err}
 	}
-	var NodeList []*corev1.Node
-	for _, node := range nodes {
-		nodeCopy := node.DeepCopy()
-		nodeCopy.Name = node.Name + "SimRun-" + labelValue
-		nodeCopy.Labels[labelKey] = labelValue
-		NodeList = append(NodeList, nodeCopy)
+	if err := r.engine.VirtualClusterAccess().AddNodes(ctx, simNode); err != nil {
+		return runResult{err: err}
+	}
+
+	simPods := make([]*corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		simPods = append(simPods, toleratingPodCopy(&pod, labelValue, taintKey))
+	}
+	if err := r.engine.VirtualClusterAccess().AddPods(ctx, simPods...); err != nil {
+		return runResult{err: err}
+	}
+
+	if _, err := simutil.WaitTillNoUnscheduledPodsOrTimeout(ctx, r.engine.VirtualClusterAccess(), schedulingWaitTimeout, time.Now()); err != nil {
+		return runResult{err: err}
 	}
-	r.engine.VirtualClusterAccess().AddNodes(ctx, NodeList...)
 
+	scheduledPods, unscheduledPods, err := r.partitionSimulationPods(ctx, simNode.Name, labelValue)
+	if err != nil {
+		return runResult{err: err}
+	}
+
+	score, err := r.score(nodePool, simNode, scheduledPods, referenceCost)
+	if err != nil {
+		return runResult{err: err}
+	}
+
+	return runResult{
+		nodePoolName:    nodePool.Name,
+		zone:            zone,
+		node:            *simNode,
+		result:          scalesim.NodeRunResult{CumulativeScore: score},
+		unscheduledPods: originalsOf(pods, unscheduledPods),
+	}
+}
+
+// score computes CumulativeScore = w_waste * (1 - avg(cpuUsed/allocatable, memUsed/allocatable)) + w_cost * (hourlyCost/referenceCost)
+func (r *Recommender) score(nodePool scalesim.NodePool, node *corev1.Node, scheduledPods []*corev1.Pod, referenceCost float64) (float64, error) {
+	cpuRequested, memRequested := sumPodRequests(scheduledPods)
+	cpuAllocatable := node.Status.Allocatable.Cpu().AsApproximateFloat64()
+	memAllocatable := node.Status.Allocatable.Memory().AsApproximateFloat64()
+
+	var cpuWaste, memWaste float64
+	if cpuAllocatable > 0 {
+		cpuWaste = 1 - (cpuRequested / cpuAllocatable)
+	}
+	if memAllocatable > 0 {
+		memWaste = 1 - (memRequested / memAllocatable)
+	}
+	waste := (cpuWaste + memWaste) / 2
+
+	hourlyCost, err := r.pricing.HourlyCost(nodePool.MachineType)
+	if err != nil {
+		return 0, err
+	}
+	costScore := hourlyCost / referenceCost
+
+	return r.strategyWeights.LeastWaste*waste + r.strategyWeights.LeastCost*costScore, nil
+}
+
+func (r *Recommender) partitionSimulationPods(ctx context.Context, simNodeName, labelValue string) (scheduled, unscheduled []*corev1.Pod, err error) {
 	pods, err := r.engine.VirtualClusterAccess().ListPods(ctx)
 	if err != nil {
-		runRes.err = err
-		resultCh <- runRes
-		return
+		return nil, nil, err
+	}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Labels[simulationRunLabelKey] != labelValue {
+			continue
+		}
+		if pod.Spec.NodeName == simNodeName {
+			scheduled = append(scheduled, pod)
+		} else if pod.Spec.NodeName == "" {
+			unscheduled = append(unscheduled, pod)
+		}
+	}
+	return scheduled, unscheduled, nil
+}
+
+// newSimulationNode builds the synthetic node added to the virtual cluster to
+// simulate scaling nodePool up by one node in zone. Its capacity and
+// allocatable are populated from nodePool's machine type so score can measure
+// real waste rather than always seeing a zero-capacity node.
+func newSimulationNode(nodePool scalesim.NodePool, zone, labelValue, taintKey string) (*corev1.Node, error) {
+	capacity, err := capacityForMachineType(nodePool.MachineType)
+	if err != nil {
+		return nil, err
 	}
 
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("SimRun-%s", labelValue),
+			Labels: map[string]string{
+				simulationRunLabelKey:              labelValue,
+				"topology.kubernetes.io/zone":      zone,
+				"node.kubernetes.io/instance-type": nodePool.MachineType,
+			},
+		},
+		Status: corev1.NodeStatus{
+			Capacity:    capacity,
+			Allocatable: capacity,
+		},
+	}
+	node.Spec.Taints = []corev1.Taint{
+		{
+			Key:    taintKey,
+			Value:  "true",
+			Effect: corev1.TaintEffectNoSchedule,
+		},
+	}
+	return node, nil
+}
+
+func originPodKey(pod *corev1.Pod) string {
+	return pod.Namespace + "/" + pod.Name
+}
+
+func toleratingPodCopy(pod *corev1.Pod, labelValue, taintKey string) *corev1.Pod {
+	podCopy := pod.DeepCopy()
+	podCopy.Name = pod.Name + "-sim-" + labelValue
+	podCopy.ResourceVersion = ""
+	podCopy.Spec.NodeName = ""
+	if podCopy.Labels == nil {
+		podCopy.Labels = map[string]string{}
+	}
+	podCopy.Labels[simulationRunLabelKey] = labelValue
+	if podCopy.Annotations == nil {
+		podCopy.Annotations = map[string]string{}
+	}
+	podCopy.Annotations[simulationOriginAnnoKey] = originPodKey(pod)
+	podCopy.Spec.Tolerations = append(podCopy.Spec.Tolerations, corev1.Toleration{
+		Key:      taintKey,
+		Operator: corev1.TolerationOpEqual,
+		Value:    "true",
+		Effect:   corev1.TaintEffectNoSchedule,
+	})
+	return podCopy
+}
+
+// originalsOf maps the still-unscheduled simulation pod copies back onto the
+// original pods the caller passed in via the origin annotation, so the next
+// run operates on real pods rather than the throwaway copies created for this
+// simulation.
+func originalsOf(originals []corev1.Pod, simCopies []*corev1.Pod) []corev1.Pod {
+	unscheduledKeys := make(map[string]struct{}, len(simCopies))
+	for _, pod := range simCopies {
+		unscheduledKeys[pod.Annotations[simulationOriginAnnoKey]] = struct{}{}
+	}
+	result := make([]corev1.Pod, 0, len(unscheduledKeys))
+	for _, pod := range originals {
+		if _, ok := unscheduledKeys[originPodKey(&pod)]; ok {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
+func sumPodRequests(pods []*corev1.Pod) (cpu float64, mem float64) {
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			cpu += container.Resources.Requests.Cpu().AsApproximateFloat64()
+			mem += container.Resources.Requests.Memory().AsApproximateFloat64()
+		}
+	}
+	return cpu, mem
 }
 
 func (r *Recommender) getEligibleNodePools(ctx context.Context, shoot *v1beta1.Shoot) ([]scalesim.NodePool, error) {
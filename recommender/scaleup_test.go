@@ -0,0 +1,103 @@
+package recommender
+
+import (
+	"testing"
+
+	scalesim "github.com/elankath/scaler-simulator"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func podWithRequests(cpu, mem string) *corev1.Pod {
+	return &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(cpu),
+							corev1.ResourceMemory: resource.MustParse(mem),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestScore(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "n1"},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			},
+		},
+	}
+	nodePool := scalesim.NodePool{MachineType: "m5.xlarge"}
+
+	r := &Recommender{
+		strategyWeights: StrategyWeights{LeastWaste: 0.5, LeastCost: 0.5},
+		pricing:         NewStaticInstancePricing(defaultHourlyCostByMachineType),
+	}
+
+	got, err := r.score(nodePool, node, []*corev1.Pod{podWithRequests("2", "8Gi")}, 0.192)
+	if err != nil {
+		t.Fatalf("score returned error: %v", err)
+	}
+
+	// cpuWaste = 1 - 2/4 = 0.5, memWaste = 1 - 8/16 = 0.5, waste = 0.5
+	// costScore = 0.192/0.192 = 1
+	// cumulative = 0.5*0.5 + 0.5*1 = 0.75
+	want := 0.75
+	if got != want {
+		t.Errorf("score() = %v, want %v", got, want)
+	}
+}
+
+func TestScoreZeroAllocatableYieldsNoWaste(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n1"}}
+	nodePool := scalesim.NodePool{MachineType: "m5.large"}
+
+	r := &Recommender{
+		strategyWeights: StrategyWeights{LeastWaste: 1, LeastCost: 0},
+		pricing:         NewStaticInstancePricing(defaultHourlyCostByMachineType),
+	}
+
+	got, err := r.score(nodePool, node, []*corev1.Pod{podWithRequests("1", "1Gi")}, 1)
+	if err != nil {
+		t.Fatalf("score returned error: %v", err)
+	}
+	if got != 0 {
+		t.Errorf("score() with zero allocatable = %v, want 0", got)
+	}
+}
+
+func TestNewSimulationNodePopulatesCapacity(t *testing.T) {
+	nodePool := scalesim.NodePool{MachineType: "m5.large"}
+
+	node, err := newSimulationNode(nodePool, "eu-west-1a", "pool-1-0", "taint-key")
+	if err != nil {
+		t.Fatalf("newSimulationNode returned error: %v", err)
+	}
+
+	if node.Labels["node.kubernetes.io/instance-type"] != "m5.large" {
+		t.Errorf("instance-type label = %q, want %q", node.Labels["node.kubernetes.io/instance-type"], "m5.large")
+	}
+	if node.Status.Allocatable.Cpu().IsZero() {
+		t.Error("simulated node has zero allocatable CPU")
+	}
+	if node.Status.Allocatable.Memory().IsZero() {
+		t.Error("simulated node has zero allocatable memory")
+	}
+}
+
+func TestNewSimulationNodeUnknownMachineType(t *testing.T) {
+	nodePool := scalesim.NodePool{MachineType: "does-not-exist"}
+
+	if _, err := newSimulationNode(nodePool, "eu-west-1a", "pool-1-0", "taint-key"); err == nil {
+		t.Error("expected error for unknown machine type, got nil")
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/elankath/scaler-simulator/loadgen"
+	"github.com/elankath/scaler-simulator/recommender"
+)
+
+// runRegistry tracks the cancel function for every in-flight recommender run
+// so a client can cancel a specific run, or the engine can cancel all of them
+// on shutdown. It also keeps the most recent RunReport for each run id so a
+// client can retrieve it after the run has finished.
+type runRegistry struct {
+	mu      sync.Mutex
+	nextID  uint64
+	cancels map[string]context.CancelFunc
+	reports map[string]recommender.RunReport
+	bursts  map[string]loadgen.BurstResult
+}
+
+func newRunRegistry() *runRegistry {
+	return &runRegistry{
+		cancels: make(map[string]context.CancelFunc),
+		reports: make(map[string]recommender.RunReport),
+		bursts:  make(map[string]loadgen.BurstResult),
+	}
+}
+
+// start derives a cancellable context from parent, registers it under a new
+// run id, and returns the id, the derived context, and a done func the caller
+// must invoke once the run has finished to release the registry entry.
+func (rr *runRegistry) start(parent context.Context) (id string, ctx context.Context, done func()) {
+	ctx, cancel := context.WithCancel(parent)
+
+	rr.mu.Lock()
+	rr.nextID++
+	id = fmt.Sprintf("run-%d", rr.nextID)
+	rr.cancels[id] = cancel
+	rr.mu.Unlock()
+
+	done = func() {
+		rr.mu.Lock()
+		delete(rr.cancels, id)
+		rr.mu.Unlock()
+		cancel()
+	}
+	return id, ctx, done
+}
+
+// newID returns a unique id of the form "<prefix>-N", drawing on the same
+// monotonic counter used for run and burst ids, so callers minting names
+// outside the run/burst lifecycle (e.g. synthesized pod prefixes) still get
+// one that can't collide with a concurrent request.
+func (rr *runRegistry) newID(prefix string) string {
+	rr.mu.Lock()
+	rr.nextID++
+	id := fmt.Sprintf("%s-%d", prefix, rr.nextID)
+	rr.mu.Unlock()
+	return id
+}
+
+// cancel cancels the run registered under id and releases its entry,
+// reporting whether it was found.
+func (rr *runRegistry) cancel(id string) bool {
+	rr.mu.Lock()
+	cancel, ok := rr.cancels[id]
+	delete(rr.cancels, id)
+	rr.mu.Unlock()
+	if !ok {
+		return false
+	}
+	cancel()
+	return true
+}
+
+// cancelAll cancels every in-flight run. Used on engine shutdown.
+func (rr *runRegistry) cancelAll() {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	for _, cancel := range rr.cancels {
+		cancel()
+	}
+}
+
+// setReport stores the RunReport produced by the run registered under id.
+func (rr *runRegistry) setReport(id string, report recommender.RunReport) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.reports[id] = report
+}
+
+// report returns the RunReport stored under id, if any.
+func (rr *runRegistry) report(id string) (recommender.RunReport, bool) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	report, ok := rr.reports[id]
+	return report, ok
+}
+
+// setBurstResult stores the BurstResult produced by the burst run registered
+// under id.
+func (rr *runRegistry) setBurstResult(id string, result loadgen.BurstResult) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.bursts[id] = result
+}
+
+// burstResult returns the BurstResult stored under id, if any.
+func (rr *runRegistry) burstResult(id string) (loadgen.BurstResult, bool) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	result, ok := rr.bursts[id]
+	return result, ok
+}
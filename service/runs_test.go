@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/elankath/scaler-simulator/recommender"
+)
+
+func TestRunRegistryStartAndCancel(t *testing.T) {
+	rr := newRunRegistry()
+
+	id, ctx, done := rr.start(context.Background())
+	defer done()
+
+	if id == "" {
+		t.Fatal("start returned an empty run id")
+	}
+	select {
+	case <-ctx.Done():
+		t.Fatal("run context is done before cancel")
+	default:
+	}
+
+	if !rr.cancel(id) {
+		t.Fatal("cancel reported the run id as not found")
+	}
+	select {
+	case <-ctx.Done():
+	default:
+		t.Fatal("run context is not done after cancel")
+	}
+
+	if rr.cancel(id) {
+		t.Fatal("cancel succeeded twice for the same run id")
+	}
+}
+
+func TestRunRegistryNewIDsDoNotCollide(t *testing.T) {
+	rr := newRunRegistry()
+
+	first := rr.newID("synth")
+	second := rr.newID("synth")
+
+	if first == second {
+		t.Fatalf("newID returned the same id twice: %q", first)
+	}
+}
+
+func TestRunRegistryReport(t *testing.T) {
+	rr := newRunRegistry()
+
+	if _, ok := rr.report("missing"); ok {
+		t.Fatal("report found a result for an id that was never set")
+	}
+
+	want := recommender.RunReport{Error: "boom"}
+	rr.setReport("run-1", want)
+
+	got, ok := rr.report("run-1")
+	if !ok {
+		t.Fatal("report did not find a result that was set")
+	}
+	if got.Error != want.Error {
+		t.Errorf("report() = %+v, want %+v", got, want)
+	}
+}
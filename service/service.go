@@ -1,28 +1,41 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	scalesim "github.com/elankath/scaler-simulator"
+	"github.com/elankath/scaler-simulator/recommender"
 )
 
 type engine struct {
+	ctx           context.Context
+	cancel        context.CancelFunc
 	virtualAccess scalesim.VirtualClusterAccess
 	shootAccess   scalesim.ShootAccess
 	mux           *http.ServeMux
+	runs          *runRegistry
 }
 
 var _ scalesim.Engine = (*engine)(nil)
 
-func NewEngine(virtualAccess scalesim.VirtualClusterAccess, shootAccess scalesim.ShootAccess) (scalesim.Engine, error) {
+func NewEngine(ctx context.Context, virtualAccess scalesim.VirtualClusterAccess, shootAccess scalesim.ShootAccess) (scalesim.Engine, error) {
+	engineCtx, cancel := context.WithCancel(ctx)
+	virtualAccess = withSnapshotSupport(virtualAccess)
+	runs := newRunRegistry()
 	mux := http.NewServeMux()
-	addRoutes(mux, virtualAccess, shootAccess)
+	addRoutes(mux, virtualAccess, shootAccess, runs)
 	return &engine{
+		ctx:           engineCtx,
+		cancel:        cancel,
 		virtualAccess: virtualAccess,
 		shootAccess:   shootAccess,
 		mux:           mux,
+		runs:          runs,
 	}, nil
 }
 
@@ -30,12 +43,181 @@ func (e *engine) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	e.mux.ServeHTTP(writer, request)
 }
 
-func addRoutes(mux *http.ServeMux, virtualAccess scalesim.VirtualClusterAccess, shootAccess scalesim.ShootAccess) {
+// Shutdown cancels every in-flight recommender run and the engine's own
+// context, so callers draining a result channel observe it closing rather
+// than hanging past process shutdown.
+func (e *engine) Shutdown() {
+	e.runs.cancelAll()
+	e.cancel()
+}
+
+func addRoutes(mux *http.ServeMux, virtualAccess scalesim.VirtualClusterAccess, shootAccess scalesim.ShootAccess, runs *runRegistry) {
 	mux.Handle("DELETE /api/virtual-cluster", handleClearVirtualCluster(virtualAccess))
 	mux.Handle("POST /api/sync-shoot-nodes", handleSyncShootNodes(virtualAccess, shootAccess))
+	mux.Handle("POST /api/recommend/scale-up", handleRecommendScaleUp(virtualAccess, shootAccess, runs))
+	mux.Handle("DELETE /api/recommend/scale-down", handleRecommendScaleDown(virtualAccess, runs))
+	mux.Handle("POST /api/runs/{id}/cancel", handleCancelRun(runs))
+	mux.Handle("GET /api/runs/{id}/report", handleRunReport(runs))
+	mux.Handle("POST /api/synthesize-pods", handleSynthesizePods(virtualAccess, runs))
+	mux.Handle("POST /api/synthesize-pods/burst", handleSynthesizePodsBurst(virtualAccess, shootAccess, runs))
+	mux.Handle("GET /api/synthesize-pods/burst/{id}", handleSynthesizePodsBurstResult(runs))
+	mux.Handle("POST /api/virtual-cluster/snapshot", handleSnapshotVirtualCluster(virtualAccess))
+	mux.Handle("POST /api/virtual-cluster/restore/{id}", handleRestoreVirtualCluster(virtualAccess))
 	//mux.Handle("GET /api/bingo", http.HandleFunc())
 }
 
+// handleRecommendScaleUp runs the scale-up recommender against the current
+// virtual cluster. The `leastWaste` and `leastCost` query params let callers
+// bias the recommendation between minimizing unused capacity and minimizing
+// hourly cost; both default to an even 0.5/0.5 split. The run is registered
+// so it can be cancelled via POST /api/runs/{id}/cancel, and is automatically
+// cancelled if the client disconnects.
+func handleRecommendScaleUp(virtualAccess scalesim.VirtualClusterAccess, shootAccess scalesim.ShootAccess, runs *runRegistry) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			shootName := r.URL.Query().Get("shoot")
+			scenarioName := r.URL.Query().Get("scenario")
+			weights := recommender.StrategyWeights{
+				LeastWaste: queryFloatOrDefault(r, "leastWaste", 0.5),
+				LeastCost:  queryFloatOrDefault(r, "leastCost", 0.5),
+			}
+
+			shootNodes, err := shootAccess.GetNodes()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			runID, runCtx, done := runs.start(r.Context())
+			defer done()
+			w.Header().Set("X-Run-Id", runID)
+
+			rec := recommender.NewRecommender(&engine{virtualAccess: virtualAccess, shootAccess: shootAccess}, shootNodes, scenarioName, shootName, weights, w)
+			recommendation, report, err := rec.Run(runCtx)
+			runs.setReport(runID, report)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(recommendation); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+		},
+	)
+}
+
+func queryFloatOrDefault(r *http.Request, param string, fallback float64) float64 {
+	raw := r.URL.Query().Get(param)
+	if raw == "" {
+		return fallback
+	}
+	val, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return fallback
+	}
+	return val
+}
+
+// handleRecommendScaleDown evaluates every node in the virtual cluster, most
+// expensive machine type first, and virtually removes the ones whose pods can
+// be safely rescheduled elsewhere without violating any PodDisruptionBudget.
+func handleRecommendScaleDown(virtualAccess scalesim.VirtualClusterAccess, runs *runRegistry) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			nodes, err := virtualAccess.ListNodes(r.Context())
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			runID, runCtx, done := runs.start(r.Context())
+			defer done()
+			w.Header().Set("X-Run-Id", runID)
+
+			recommendation, err := recommender.ScaleDownOrderedByDescendingCost(runCtx, virtualAccess, w, nodes)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(recommendation); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+		},
+	)
+}
+
+// handleCancelRun cancels a specific in-flight recommendation run by id.
+func handleCancelRun(runs *runRegistry) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			id := r.PathValue("id")
+			if !runs.cancel(id) {
+				http.Error(w, fmt.Sprintf("no in-flight run with id %q", id), http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		},
+	)
+}
+
+// handleRunReport returns the RunReport recorded for a scale-up run, letting
+// a caller see per-nodepool winner scores, unscheduled counts, and errors
+// without scraping logs.
+func handleRunReport(runs *runRegistry) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			id := r.PathValue("id")
+			report, ok := runs.report(id)
+			if !ok {
+				http.Error(w, fmt.Sprintf("no report found for run id %q", id), http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(report); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+		},
+	)
+}
+
+// handleSnapshotVirtualCluster checkpoints the virtual cluster's nodes, pods,
+// and scheduler bindings so a scenario can be replayed deterministically from
+// the same starting point. PVCs are not part of the checkpoint: the virtual
+// cluster doesn't model them, so there's nothing yet for Snapshot/Restore to
+// capture.
+func handleSnapshotVirtualCluster(virtualAccess scalesim.VirtualClusterAccess) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			snapshotID, err := virtualAccess.Snapshot(r.Context())
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"snapshotId": snapshotID})
+		},
+	)
+}
+
+// handleRestoreVirtualCluster resets the virtual cluster to a previously
+// taken snapshot.
+func handleRestoreVirtualCluster(virtualAccess scalesim.VirtualClusterAccess) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			id := scalesim.SnapshotID(r.PathValue("id"))
+			if err := virtualAccess.Restore(r.Context(), id); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		},
+	)
+}
+
 func handleSyncShootNodes(virtualAccess scalesim.VirtualClusterAccess, shootAccess scalesim.ShootAccess) http.Handler {
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
@@ -64,4 +246,4 @@ func handleClearVirtualCluster(virtualAccess scalesim.VirtualClusterAccess) http
 			}
 		},
 	)
-}
\ No newline at end of file
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"sync"
+
+	scalesim "github.com/elankath/scaler-simulator"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// clusterState is the gob-serializable payload captured by Snapshot: every
+// node and pod in the virtual cluster at the time it was taken, plus the
+// pod->node bindings the virtual scheduler had assigned.
+//
+// resource.Quantity (used throughout corev1.ResourceList) keeps its amount in
+// unexported fields, so gob only round-trips its exported Format field and
+// silently decodes every capacity/request back as zero. NodeQuantities and
+// PodQuantities carry the canonical string form of each quantity alongside
+// the gob payload so reapplyQuantities can restore the real amounts after
+// decode.
+type clusterState struct {
+	Nodes    []corev1.Node
+	Pods     []corev1.Pod
+	Bindings map[string]string // pod namespace/name -> node name
+
+	NodeQuantities map[string]nodeQuantities        // node name -> capacity/allocatable
+	PodQuantities  map[string][]containerQuantities // pod namespace/name -> per-container requests/limits
+}
+
+type nodeQuantities struct {
+	Capacity    map[corev1.ResourceName]string
+	Allocatable map[corev1.ResourceName]string
+}
+
+type containerQuantities struct {
+	Requests map[corev1.ResourceName]string
+	Limits   map[corev1.ResourceName]string
+}
+
+// captureResourceList records rl's quantities as their canonical string form
+// (the same form resource.Quantity.String()/resource.ParseQuantity use for
+// JSON), since gob cannot carry a Quantity's unexported amount directly.
+func captureResourceList(rl corev1.ResourceList) map[corev1.ResourceName]string {
+	if rl == nil {
+		return nil
+	}
+	out := make(map[corev1.ResourceName]string, len(rl))
+	for name, qty := range rl {
+		out[name] = qty.String()
+	}
+	return out
+}
+
+func resourceListFromStrings(m map[corev1.ResourceName]string) corev1.ResourceList {
+	if m == nil {
+		return nil
+	}
+	out := make(corev1.ResourceList, len(m))
+	for name, s := range m {
+		out[name] = resource.MustParse(s)
+	}
+	return out
+}
+
+// captureQuantities records the real amount of every resource.Quantity
+// reachable from nodes/pods, keyed so reapplyQuantities can put them back
+// after a gob round-trip has zeroed them out.
+func captureQuantities(nodes []corev1.Node, pods []corev1.Pod) (map[string]nodeQuantities, map[string][]containerQuantities) {
+	nodeQs := make(map[string]nodeQuantities, len(nodes))
+	for _, node := range nodes {
+		nodeQs[node.Name] = nodeQuantities{
+			Capacity:    captureResourceList(node.Status.Capacity),
+			Allocatable: captureResourceList(node.Status.Allocatable),
+		}
+	}
+
+	podQs := make(map[string][]containerQuantities, len(pods))
+	for _, pod := range pods {
+		containers := make([]containerQuantities, len(pod.Spec.Containers))
+		for i, c := range pod.Spec.Containers {
+			containers[i] = containerQuantities{
+				Requests: captureResourceList(c.Resources.Requests),
+				Limits:   captureResourceList(c.Resources.Limits),
+			}
+		}
+		podQs[pod.Namespace+"/"+pod.Name] = containers
+	}
+
+	return nodeQs, podQs
+}
+
+// reapplyQuantities restores the real amounts captured by captureQuantities
+// onto state's nodes and pods, undoing the zeroing a gob round-trip does to
+// every resource.Quantity.
+func reapplyQuantities(state *clusterState) {
+	for i := range state.Nodes {
+		node := &state.Nodes[i]
+		nq, ok := state.NodeQuantities[node.Name]
+		if !ok {
+			continue
+		}
+		node.Status.Capacity = resourceListFromStrings(nq.Capacity)
+		node.Status.Allocatable = resourceListFromStrings(nq.Allocatable)
+	}
+	for i := range state.Pods {
+		pod := &state.Pods[i]
+		cqs, ok := state.PodQuantities[pod.Namespace+"/"+pod.Name]
+		if !ok {
+			continue
+		}
+		for ci := range pod.Spec.Containers {
+			if ci >= len(cqs) {
+				break
+			}
+			pod.Spec.Containers[ci].Resources.Requests = resourceListFromStrings(cqs[ci].Requests)
+			pod.Spec.Containers[ci].Resources.Limits = resourceListFromStrings(cqs[ci].Limits)
+		}
+	}
+}
+
+// snapshottingVirtualClusterAccess decorates a scalesim.VirtualClusterAccess
+// with real Snapshot/Restore support: Snapshot gob-encodes every node and pod
+// currently in the virtual cluster (plus each pod's node binding) into an
+// in-memory checkpoint, and Restore clears the virtual cluster and replays a
+// checkpoint back in. Every other method is forwarded unchanged via the
+// embedded interface.
+type snapshottingVirtualClusterAccess struct {
+	scalesim.VirtualClusterAccess
+
+	mu        sync.Mutex
+	nextID    uint64
+	snapshots map[scalesim.SnapshotID][]byte
+}
+
+// withSnapshotSupport wraps access so Snapshot/Restore are backed by a real
+// gob-serialized checkpoint of its nodes and pods, rather than leaving each
+// caller to hand-clean up its own simulation objects.
+func withSnapshotSupport(access scalesim.VirtualClusterAccess) scalesim.VirtualClusterAccess {
+	return &snapshottingVirtualClusterAccess{
+		VirtualClusterAccess: access,
+		snapshots:            make(map[scalesim.SnapshotID][]byte),
+	}
+}
+
+func (s *snapshottingVirtualClusterAccess) Snapshot(ctx context.Context) (scalesim.SnapshotID, error) {
+	state, err := s.captureState(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(state); err != nil {
+		return "", fmt.Errorf("encoding virtual cluster snapshot: %w", err)
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	id := scalesim.SnapshotID(fmt.Sprintf("snap-%d", s.nextID))
+	s.snapshots[id] = buf.Bytes()
+	s.mu.Unlock()
+
+	return id, nil
+}
+
+func (s *snapshottingVirtualClusterAccess) Restore(ctx context.Context, id scalesim.SnapshotID) error {
+	s.mu.Lock()
+	raw, ok := s.snapshots[id]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no snapshot found for id %q", id)
+	}
+
+	var state clusterState
+	if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&state); err != nil {
+		return fmt.Errorf("decoding virtual cluster snapshot %q: %w", id, err)
+	}
+
+	if err := s.VirtualClusterAccess.ClearAll(ctx); err != nil {
+		return err
+	}
+	return s.replayState(ctx, state)
+}
+
+func (s *snapshottingVirtualClusterAccess) captureState(ctx context.Context) (clusterState, error) {
+	nodes, err := s.VirtualClusterAccess.ListNodes(ctx)
+	if err != nil {
+		return clusterState{}, err
+	}
+	pods, err := s.VirtualClusterAccess.ListPods(ctx)
+	if err != nil {
+		return clusterState{}, err
+	}
+
+	bindings := make(map[string]string, len(pods))
+	for _, pod := range pods {
+		if pod.Spec.NodeName != "" {
+			bindings[pod.Namespace+"/"+pod.Name] = pod.Spec.NodeName
+		}
+	}
+
+	nodeQuantities, podQuantities := captureQuantities(nodes, pods)
+
+	return clusterState{
+		Nodes:          nodes,
+		Pods:           pods,
+		Bindings:       bindings,
+		NodeQuantities: nodeQuantities,
+		PodQuantities:  podQuantities,
+	}, nil
+}
+
+func (s *snapshottingVirtualClusterAccess) replayState(ctx context.Context, state clusterState) error {
+	reapplyQuantities(&state)
+
+	nodes := make([]*corev1.Node, 0, len(state.Nodes))
+	for i := range state.Nodes {
+		nodes = append(nodes, &state.Nodes[i])
+	}
+	if len(nodes) > 0 {
+		if err := s.VirtualClusterAccess.AddNodes(ctx, nodes...); err != nil {
+			return err
+		}
+	}
+
+	pods := make([]*corev1.Pod, 0, len(state.Pods))
+	for i := range state.Pods {
+		pod := &state.Pods[i]
+		pod.Spec.NodeName = state.Bindings[pod.Namespace+"/"+pod.Name]
+		pods = append(pods, pod)
+	}
+	if len(pods) > 0 {
+		if err := s.VirtualClusterAccess.AddPods(ctx, pods...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterStateGobRoundTrip(t *testing.T) {
+	want := clusterState{
+		Nodes: []corev1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		},
+		Pods: []corev1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1"}},
+		},
+		Bindings: map[string]string{"default/pod-1": "node-1"},
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(want); err != nil {
+		t.Fatalf("encoding clusterState: %v", err)
+	}
+
+	var got clusterState
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatalf("decoding clusterState: %v", err)
+	}
+
+	if len(got.Nodes) != 1 || got.Nodes[0].Name != "node-1" {
+		t.Errorf("Nodes = %+v, want one node named node-1", got.Nodes)
+	}
+	if len(got.Pods) != 1 || got.Pods[0].Name != "pod-1" {
+		t.Errorf("Pods = %+v, want one pod named pod-1", got.Pods)
+	}
+	if got.Bindings["default/pod-1"] != "node-1" {
+		t.Errorf("Bindings[default/pod-1] = %q, want node-1", got.Bindings["default/pod-1"])
+	}
+}
+
+// TestClusterStateGobRoundTripPreservesQuantities guards against gob's
+// handling of resource.Quantity: it only carries a Quantity's exported
+// Format field, so without NodeQuantities/PodQuantities a node's Allocatable
+// and a pod's resource requests would silently decode back as zero.
+func TestClusterStateGobRoundTripPreservesQuantities(t *testing.T) {
+	node := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			},
+		},
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("250m"),
+							corev1.ResourceMemory: resource.MustParse("512Mi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	nodeQuantities, podQuantities := captureQuantities([]corev1.Node{node}, []corev1.Pod{pod})
+	want := clusterState{
+		Nodes:          []corev1.Node{node},
+		Pods:           []corev1.Pod{pod},
+		NodeQuantities: nodeQuantities,
+		PodQuantities:  podQuantities,
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(want); err != nil {
+		t.Fatalf("encoding clusterState: %v", err)
+	}
+
+	var got clusterState
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatalf("decoding clusterState: %v", err)
+	}
+	reapplyQuantities(&got)
+
+	gotAllocatable := got.Nodes[0].Status.Allocatable
+	if !gotAllocatable.Cpu().Equal(resource.MustParse("4")) {
+		t.Errorf("Allocatable[cpu] = %v, want 4", gotAllocatable.Cpu())
+	}
+	if !gotAllocatable.Memory().Equal(resource.MustParse("16Gi")) {
+		t.Errorf("Allocatable[memory] = %v, want 16Gi", gotAllocatable.Memory())
+	}
+
+	gotRequests := got.Pods[0].Spec.Containers[0].Resources.Requests
+	if !gotRequests.Cpu().Equal(resource.MustParse("250m")) {
+		t.Errorf("Requests[cpu] = %v, want 250m", gotRequests.Cpu())
+	}
+	if !gotRequests.Memory().Equal(resource.MustParse("512Mi")) {
+		t.Errorf("Requests[memory] = %v, want 512Mi", gotRequests.Memory())
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	scalesim "github.com/elankath/scaler-simulator"
+	"github.com/elankath/scaler-simulator/loadgen"
+	"github.com/elankath/scaler-simulator/recommender"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// synthesizePodsRequest is the JSON body accepted by POST /api/synthesize-pods.
+type synthesizePodsRequest struct {
+	Count                     int                               `json:"count"`
+	CPURequest                string                            `json:"cpuRequest"`
+	MemRequest                string                            `json:"memRequest"`
+	NodeSelector              map[string]string                 `json:"nodeSelector"`
+	Tolerations               []corev1.Toleration               `json:"tolerations"`
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints"`
+	OwnerKind                 loadgen.OwnerKind                 `json:"ownerKind"`
+}
+
+// handleSynthesizePods creates count synthetic unscheduled pods matching the
+// requested spec in the virtual cluster, so scenarios can drive the
+// recommender without hand-writing YAML per case. Each call gets its own
+// name prefix so repeated calls don't collide on pod names.
+func handleSynthesizePods(virtualAccess scalesim.VirtualClusterAccess, runs *runRegistry) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var req synthesizePodsRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			namePrefix := runs.newID("synth")
+			pods, err := loadgen.SynthesizePods(r.Context(), virtualAccess, namePrefix, toPodSpec(req))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]int{"created": len(pods)})
+		},
+	)
+}
+
+// synthesizeBurstRequest is the JSON body accepted by
+// POST /api/synthesize-pods/burst.
+type synthesizeBurstRequest struct {
+	PodsPerSecond int                   `json:"podsPerSecond"`
+	DurationSecs  int                   `json:"durationSecs"`
+	Shoot         string                `json:"shoot"`
+	Scenario      string                `json:"scenario"`
+	PodTemplate   synthesizePodsRequest `json:"podTemplate"`
+}
+
+// handleSynthesizePodsBurst ramps PodsPerSecond synthetic pods per second for
+// DurationSecs, running the scale-up recommender after every tick and
+// recording its recommendation for later retrieval via
+// GET /api/synthesize-pods/burst/{id}.
+func handleSynthesizePodsBurst(virtualAccess scalesim.VirtualClusterAccess, shootAccess scalesim.ShootAccess, runs *runRegistry) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var req synthesizeBurstRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			shootNodes, err := shootAccess.GetNodes()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			runID, runCtx, done := runs.start(r.Context())
+			defer done()
+			w.Header().Set("X-Run-Id", runID)
+
+			namePrefix := runs.newID("burst")
+			result, err := loadgen.RunBurst(runCtx, &engine{virtualAccess: virtualAccess, shootAccess: shootAccess}, shootNodes, req.Scenario, req.Shoot, recommender.StrategyWeights{LeastWaste: 0.5, LeastCost: 0.5}, w, namePrefix, loadgen.BurstSpec{
+				PodsPerSecond: req.PodsPerSecond,
+				Duration:      time.Duration(req.DurationSecs) * time.Second,
+				PodTemplate:   toPodSpec(req.PodTemplate),
+			})
+			runs.setBurstResult(runID, result)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(result)
+		},
+	)
+}
+
+func toPodSpec(req synthesizePodsRequest) loadgen.PodSpec {
+	return loadgen.PodSpec{
+		Count:                     req.Count,
+		CPURequest:                req.CPURequest,
+		MemRequest:                req.MemRequest,
+		NodeSelector:              req.NodeSelector,
+		Tolerations:               req.Tolerations,
+		TopologySpreadConstraints: req.TopologySpreadConstraints,
+		OwnerKind:                 req.OwnerKind,
+	}
+}
+
+// handleSynthesizePodsBurstResult returns the BurstResult recorded for a
+// synthesize-pods burst run.
+func handleSynthesizePodsBurstResult(runs *runRegistry) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			id := r.PathValue("id")
+			result, ok := runs.burstResult(id)
+			if !ok {
+				http.Error(w, fmt.Sprintf("no burst result found for run id %q", id), http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(result)
+		},
+	)
+}